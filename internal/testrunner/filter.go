@@ -0,0 +1,41 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package testrunner
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// Filter selects a subset of test cases to run, in the style of `go test -run`. An
+// empty Filter matches everything.
+type Filter struct {
+	pattern *regexp.Regexp
+}
+
+// NewFilter compiles expr (a regular expression matched against "package/data_stream/test_name")
+// into a Filter. An empty expr matches every test case.
+func NewFilter(expr string) (Filter, error) {
+	if expr == "" {
+		return Filter{}, nil
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return Filter{}, errors.Wrapf(err, "invalid filter expression: %s", expr)
+	}
+	return Filter{pattern: pattern}, nil
+}
+
+// Match reports whether the test case identified by package, data stream and name is
+// selected by the filter.
+func (f Filter) Match(pkg, dataStream, name string) bool {
+	if f.pattern == nil {
+		return true
+	}
+	return f.pattern.MatchString(fmt.Sprintf("%s/%s/%s", pkg, dataStream, name))
+}
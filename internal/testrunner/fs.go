@@ -0,0 +1,31 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package testrunner
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// FS abstracts the filesystem access needed by test runners, so that runners can be
+// pointed at an in-memory or embedded filesystem (e.g. in unit tests) instead of always
+// reading test fixtures from disk.
+type FS interface {
+	ReadDir(path string) ([]os.FileInfo, error)
+	ReadFile(path string) ([]byte, error)
+}
+
+// OSFS is the default FS implementation, backed by the local filesystem.
+type OSFS struct{}
+
+// ReadDir lists the entries of the given directory.
+func (OSFS) ReadDir(path string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(path)
+}
+
+// ReadFile reads the whole contents of the given file.
+func (OSFS) ReadFile(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package testrunner
+
+import "fmt"
+
+// TestReportFormat represents a test report format.
+type TestReportFormat string
+
+// FormatFunc renders a set of test results as a string in a given report format.
+type FormatFunc func(results []TestResult) (string, error)
+
+var reporterFormats = map[TestReportFormat]FormatFunc{}
+
+// RegisterReporterFormat method registers the report format function for the given format.
+func RegisterReporterFormat(format TestReportFormat, f FormatFunc) {
+	reporterFormats[format] = f
+}
+
+// FormatReport method delegates rendering to the report format function registered for the given format.
+func FormatReport(format TestReportFormat, results []TestResult) (string, error) {
+	f, defined := reporterFormats[format]
+	if !defined {
+		return "", fmt.Errorf("unregistered report format: %s", format)
+	}
+	return f(results)
+}
+
+// TestReporter represents a legacy test reporter name (deprecated in favor of TestReportFormat).
+type TestReporter string
+
+// ReporterFunc renders a set of test results as a string (legacy reporter signature).
+type ReporterFunc func(results []TestResult) (string, error)
+
+var reporters = map[TestReporter]ReporterFunc{}
+
+// RegisterReporter method registers the legacy reporter function for the given reporter name.
+//
+// Deprecated: use RegisterReporterFormat instead.
+func RegisterReporter(reporter TestReporter, f ReporterFunc) {
+	reporters[reporter] = f
+}
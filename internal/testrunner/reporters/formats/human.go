@@ -0,0 +1,109 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package formats
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mgutz/ansi"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+func init() {
+	testrunner.RegisterReporterFormat(ReportFormatHuman, reportHumanFormat)
+}
+
+// ReportFormatHuman reports test results as a colorized, per-package/per-data-stream table
+// with totals and a final PASS/FAIL banner, intended for a developer's terminal rather than
+// machine consumption.
+const ReportFormatHuman testrunner.TestReportFormat = "human"
+
+type humanTally struct {
+	passed, failed, errors, skipped int
+}
+
+func (t humanTally) total() int {
+	return t.passed + t.failed + t.errors + t.skipped
+}
+
+func (t humanTally) ok() bool {
+	return t.failed == 0 && t.errors == 0
+}
+
+// reportHumanFormat renders one row per package/data stream, a grand total row, and a final
+// PASS/FAIL banner, colorizing counts so failures and errors stand out on a terminal.
+func reportHumanFormat(results []testrunner.TestResult) (string, error) {
+	// test type => package => data stream => tally
+	type key struct{ testType, pkg, dataStream string }
+	order := make([]key, 0)
+	tallies := map[key]humanTally{}
+
+	for _, r := range results {
+		k := key{string(r.TestType), r.Package, r.DataStream}
+		if _, exists := tallies[k]; !exists {
+			order = append(order, k)
+		}
+
+		t := tallies[k]
+		switch {
+		case r.Skipped:
+			t.skipped++
+		case r.ErrorMsg != "":
+			t.errors++
+		case r.FailureMsg != "":
+			t.failed++
+		default:
+			t.passed++
+		}
+		tallies[k] = t
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TEST TYPE\tPACKAGE\tDATA STREAM\tPASSED\tFAILED\tERRORS\tSKIPPED")
+
+	var grand humanTally
+	for _, k := range order {
+		t := tallies[k]
+		grand.passed += t.passed
+		grand.failed += t.failed
+		grand.errors += t.errors
+		grand.skipped += t.skipped
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			k.testType, k.pkg, k.dataStream,
+			ansi.Color(fmt.Sprint(t.passed), "green"),
+			colorIfNonZero(t.failed, "red"),
+			colorIfNonZero(t.errors, "red"),
+			colorIfNonZero(t.skipped, "yellow"))
+	}
+
+	fmt.Fprintf(w, "TOTAL\t\t\t%s\t%s\t%s\t%s\n",
+		ansi.Color(fmt.Sprint(grand.passed), "green"),
+		colorIfNonZero(grand.failed, "red"),
+		colorIfNonZero(grand.errors, "red"),
+		colorIfNonZero(grand.skipped, "yellow"))
+
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	banner := ansi.Color(fmt.Sprintf(" PASS (%d tests) ", grand.total()), "green+b")
+	if !grand.ok() {
+		banner = ansi.Color(fmt.Sprintf(" FAIL (%d failed, %d errors, %d tests) ", grand.failed, grand.errors, grand.total()), "red+b")
+	}
+
+	return sb.String() + "\n" + banner + "\n", nil
+}
+
+func colorIfNonZero(n int, style string) string {
+	if n == 0 {
+		return fmt.Sprint(n)
+	}
+	return ansi.Color(fmt.Sprint(n), style)
+}
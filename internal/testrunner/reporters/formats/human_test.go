@@ -0,0 +1,115 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package formats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mgutz/ansi"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+func TestReportHumanFormat(t *testing.T) {
+	results := []testrunner.TestResult{
+		{
+			TestType:    "pipeline",
+			Package:     "apache",
+			DataStream:  "access",
+			Name:        "test for access.log",
+			TimeElapsed: 1200 * time.Millisecond,
+		},
+		{
+			TestType:   "pipeline",
+			Package:    "apache",
+			DataStream: "access",
+			Name:       "test for error.log",
+			FailureMsg: "fields did not match",
+		},
+	}
+
+	report, err := reportHumanFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(report, "apache") || !strings.Contains(report, "access") {
+		t.Errorf("expected report to mention the package and data stream, got:\n%s", report)
+	}
+	if !strings.Contains(report, "TOTAL") {
+		t.Errorf("expected report to contain a totals row, got:\n%s", report)
+	}
+	if !strings.Contains(report, " FAIL (") {
+		t.Errorf("expected report to end with a FAIL banner, got:\n%s", report)
+	}
+}
+
+func TestReportHumanFormatAllPassed(t *testing.T) {
+	results := []testrunner.TestResult{
+		{TestType: "pipeline", Package: "apache", DataStream: "access", Name: "test for access.log"},
+	}
+
+	report, err := reportHumanFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report, " PASS (") {
+		t.Errorf("expected report to end with a PASS banner, got:\n%s", report)
+	}
+}
+
+// TestReportHumanFormatGroupsByPackageAndDataStream guards the one thing that's specific to
+// this format among the reporters: results are tallied per package/data stream row, with a
+// single TOTAL row summing across all of them, rather than one row per result.
+func TestReportHumanFormatGroupsByPackageAndDataStream(t *testing.T) {
+	results := []testrunner.TestResult{
+		{TestType: "pipeline", Package: "apache", DataStream: "access", Name: "test for access.log"},
+		{TestType: "pipeline", Package: "apache", DataStream: "access", Name: "test for other.log"},
+		{TestType: "pipeline", Package: "nginx", DataStream: "error", Name: "test for error.log", ErrorMsg: "simulate call failed"},
+		{TestType: "pipeline", Package: "nginx", DataStream: "error", Name: "test for skipped.log", Skipped: true},
+	}
+
+	report, err := reportHumanFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(report, "\n")
+	var apacheRow, nginxRow, totalRow string
+	for _, line := range lines {
+		switch {
+		case strings.Contains(line, "apache"):
+			apacheRow = line
+		case strings.Contains(line, "nginx"):
+			nginxRow = line
+		case strings.HasPrefix(line, "TOTAL"):
+			totalRow = line
+		}
+	}
+
+	if !strings.Contains(apacheRow, ansi.Color("2", "green")) {
+		t.Errorf("expected the apache row to report 2 passed tests, got: %q", apacheRow)
+	}
+	if nginxRow == "" {
+		t.Errorf("expected a row for the nginx package, got none in:\n%s", report)
+	}
+	if totalRow == "" {
+		t.Fatalf("expected a TOTAL row, got none in:\n%s", report)
+	}
+	if !strings.Contains(report, " FAIL (") {
+		t.Errorf("expected an error in one data stream to produce a FAIL banner, got:\n%s", report)
+	}
+}
+
+func TestColorIfNonZero(t *testing.T) {
+	if got := colorIfNonZero(0, "red"); got != "0" {
+		t.Errorf(`expected a zero count to render uncolorized as "0", got %q`, got)
+	}
+	if got := colorIfNonZero(3, "red"); got == "3" {
+		t.Errorf("expected a non-zero count to be colorized (differ from the plain number), got %q", got)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package formats
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+func init() {
+	testrunner.RegisterReporterFormat(ReportFormatJSON, reportJSONFormat)
+}
+
+// ReportFormatJSON reports test results as a single JSON document, suitable for machine
+// consumption and long-term storage in Elasticsearch itself.
+const ReportFormatJSON testrunner.TestReportFormat = "json"
+
+// newRunID generates the run_id reported for a batch of results. It's a package var so
+// tests can produce deterministic output.
+var newRunID = func() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+type jsonSummary struct {
+	Tests    int `json:"tests"`
+	Failures int `json:"failures"`
+	Errors   int `json:"errors"`
+	Skipped  int `json:"skipped"`
+}
+
+type jsonLogs struct {
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+}
+
+type jsonResult struct {
+	Package        string             `json:"package"`
+	DataStream     string             `json:"data_stream"`
+	TestType       string             `json:"test_type"`
+	Name           string             `json:"name"`
+	Status         string             `json:"status"`
+	DurationNs     int64              `json:"duration_ns"`
+	Error          string             `json:"error,omitempty"`
+	Failure        string             `json:"failure,omitempty"`
+	FailureDetails string             `json:"failure_details,omitempty"`
+	Logs           *jsonLogs          `json:"logs,omitempty"`
+	Iterations     int                `json:"iterations,omitempty"`
+	Metrics        map[string]float64 `json:"metrics,omitempty"`
+}
+
+// jsonReport is the top-level document produced by reportJSONFormat.
+type jsonReport struct {
+	RunID      string       `json:"run_id"`
+	StartedAt  string       `json:"started_at"`
+	DurationNs int64        `json:"duration_ns"`
+	Summary    jsonSummary  `json:"summary"`
+	Results    []jsonResult `json:"results"`
+}
+
+// reportJSONFormat renders results as a single JSON document matching the schema:
+// {run_id, started_at, duration_ns, summary, results}.
+func reportJSONFormat(results []testrunner.TestResult) (string, error) {
+	jsonResults := make([]jsonResult, 0, len(results))
+
+	var totalDuration time.Duration
+	var numFailures, numErrors, numSkipped int
+	for _, r := range results {
+		totalDuration += r.TimeElapsed
+
+		jr := jsonResult{
+			Package:    r.Package,
+			DataStream: r.DataStream,
+			TestType:   string(r.TestType),
+			Name:       r.Name,
+			DurationNs: r.TimeElapsed.Nanoseconds(),
+			Iterations: r.Iterations,
+			Metrics:    r.Metrics,
+		}
+		if r.Stdout != "" || r.Stderr != "" {
+			jr.Logs = &jsonLogs{Stdout: r.Stdout, Stderr: r.Stderr}
+		}
+
+		switch {
+		case r.Skipped:
+			numSkipped++
+			jr.Status = "skipped"
+		case r.ErrorMsg != "":
+			numErrors++
+			jr.Status = "error"
+			jr.Error = r.ErrorMsg
+		case r.FailureMsg != "":
+			numFailures++
+			jr.Status = "failed"
+			jr.Failure = r.FailureMsg
+			jr.FailureDetails = r.FailureDetails
+		default:
+			jr.Status = "passed"
+		}
+
+		jsonResults = append(jsonResults, jr)
+	}
+
+	report := jsonReport{
+		RunID:      newRunID(),
+		StartedAt:  timeNow().UTC().Format(time.RFC3339),
+		DurationNs: totalDuration.Nanoseconds(),
+		Summary: jsonSummary{
+			Tests:    len(results),
+			Failures: numFailures,
+			Errors:   numErrors,
+			Skipped:  numSkipped,
+		},
+		Results: jsonResults,
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling test report as JSON failed")
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,148 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package formats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+func TestReportJSONFormat(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2020, 10, 21, 10, 0, 0, 0, time.UTC) }
+	newRunID = func() string { return "deadbeef" }
+	defer func() {
+		timeNow = time.Now
+		newRunID = func() string { return "" }
+	}()
+
+	results := []testrunner.TestResult{
+		{
+			TestType:    "pipeline",
+			Package:     "apache",
+			DataStream:  "access",
+			Name:        "test for access.log",
+			TimeElapsed: 1200 * time.Millisecond,
+			Stdout:      "simulated pipeline output",
+		},
+		{
+			TestType:       "pipeline",
+			Package:        "apache",
+			DataStream:     "access",
+			Name:           "test for error.log",
+			TimeElapsed:    250 * time.Millisecond,
+			FailureMsg:     "fields did not match",
+			FailureDetails: `expected.geo.city_name: got "", want "Paris"`,
+		},
+		{
+			TestType:   "pipeline",
+			Package:    "apache",
+			DataStream: "access",
+			Name:       "test for skipped.log",
+			Skipped:    true,
+			SkipReason: "fixture pending review",
+		},
+	}
+
+	report, err := reportJSONFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed struct {
+		RunID     string `json:"run_id"`
+		StartedAt string `json:"started_at"`
+		Summary   struct {
+			Tests, Failures, Errors, Skipped int
+		} `json:"summary"`
+		Results []struct {
+			Status string `json:"status"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(report), &parsed); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, report)
+	}
+
+	if parsed.RunID != "deadbeef" {
+		t.Errorf("expected run_id deadbeef, got %s", parsed.RunID)
+	}
+	if parsed.StartedAt != "2020-10-21T10:00:00Z" {
+		t.Errorf("expected started_at 2020-10-21T10:00:00Z, got %s", parsed.StartedAt)
+	}
+	if parsed.Summary.Tests != 3 || parsed.Summary.Failures != 1 || parsed.Summary.Skipped != 1 {
+		t.Errorf("unexpected summary: %+v", parsed.Summary)
+	}
+	if len(parsed.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(parsed.Results))
+	}
+	if parsed.Results[1].Status != "failed" {
+		t.Errorf("expected second result status failed, got %s", parsed.Results[1].Status)
+	}
+}
+
+// TestReportJSONFormatMetrics guards the one thing that's specific to this format among the
+// reporters: a benchmark result's Iterations/Metrics round-tripping into the "iterations"/
+// "metrics" fields, and being omitted entirely for a result that has neither.
+func TestReportJSONFormatMetrics(t *testing.T) {
+	results := []testrunner.TestResult{
+		{
+			TestType:   "benchmark",
+			Package:    "nginx",
+			DataStream: "error",
+			Name:       "benchmark for error.log",
+			Iterations: 100,
+			Metrics:    map[string]float64{"latency_p95_ms": 12.5, "throughput_eps": 842},
+		},
+		{
+			TestType:   "pipeline",
+			Package:    "nginx",
+			DataStream: "error",
+			Name:       "test for error.log",
+		},
+	}
+
+	report, err := reportJSONFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed struct {
+		Results []struct {
+			Iterations int                `json:"iterations"`
+			Metrics    map[string]float64 `json:"metrics"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(report), &parsed); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, report)
+	}
+
+	if parsed.Results[0].Iterations != 100 {
+		t.Errorf("expected iterations 100, got %d", parsed.Results[0].Iterations)
+	}
+	if parsed.Results[0].Metrics["latency_p95_ms"] != 12.5 {
+		t.Errorf("expected latency_p95_ms 12.5, got %v", parsed.Results[0].Metrics)
+	}
+	if parsed.Results[1].Iterations != 0 || parsed.Results[1].Metrics != nil {
+		t.Errorf("expected non-benchmark result to omit iterations/metrics, got %+v", parsed.Results[1])
+	}
+	if !strings.Contains(report, `"iterations"`) {
+		t.Errorf("expected report to contain an iterations field, got:\n%s", report)
+	}
+}
+
+// TestReportJSONFormatEmptyResults guards against a nil "results" field (which some JSON
+// consumers treat differently from an empty array) when there are no results to report.
+func TestReportJSONFormatEmptyResults(t *testing.T) {
+	report, err := reportJSONFormat(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report, `"results":[]`) {
+		t.Errorf(`expected report to contain "results":[], got:%s`, report)
+	}
+}
@@ -7,6 +7,9 @@ package formats
 import (
 	"encoding/xml"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,108 +19,255 @@ import (
 
 func init() {
 	testrunner.RegisterReporterFormat(ReportFormatXUnit, reportXUnitFormat)
+	testrunner.RegisterReporterFormat(ReportFormatXUnitLegacy, reportXUnitLegacyFormat)
 }
 
 const (
-	// ReportFormatXUnit reports test results in the xUnit format
+	// ReportFormatXUnit reports test results in the JUnit/xUnit XML format.
 	ReportFormatXUnit testrunner.TestReportFormat = "xUnit"
+
+	// ReportFormatXUnitLegacy reports test results using the original, non-standard
+	// xUnit layout (failures/errors as text attributes). Kept for backward compatibility
+	// with consumers that already parse this shape.
+	ReportFormatXUnitLegacy testrunner.TestReportFormat = "xUnit-legacy"
+)
+
+// timeNow and osHostname are indirections over time.Now and os.Hostname so that tests
+// can produce deterministic output.
+var (
+	timeNow    = time.Now
+	osHostname = os.Hostname
 )
 
 type testSuites struct {
 	XMLName xml.Name    `xml:"testsuites"`
 	Suites  []testSuite `xml:"testsuite"`
 }
+
 type testSuite struct {
 	Comment string `xml:",comment"`
 
-	Name        string `xml:"name,attr"`
-	NumTests    int    `xml:"tests,attr,omitempty"`
-	NumFailures int    `xml:"failures,attr,omitempty"`
-	NumErrors   int    `xml:"errors,attr,omitempty"`
+	Name        string  `xml:"name,attr"`
+	Timestamp   string  `xml:"timestamp,attr,omitempty"`
+	Hostname    string  `xml:"hostname,attr,omitempty"`
+	NumTests    int     `xml:"tests,attr"`
+	NumFailures int     `xml:"failures,attr"`
+	NumErrors   int     `xml:"errors,attr"`
+	NumSkipped  int     `xml:"skipped,attr"`
+	Time        float64 `xml:"time,attr"`
+
+	Properties *properties `xml:"properties,omitempty"`
 
 	Suites []testSuite `xml:"testsuite,omitempty"`
 	Cases  []testCase  `xml:"testcase,omitempty"`
 }
+
+type properties struct {
+	Property []property `xml:"property"`
+}
+
+type property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
 type testCase struct {
-	Name      string        `xml:"name,attr"`
-	ClassName string        `xml:"classname,attr`
-	Time      time.Duration `xml:"time,attr"`
+	Name      string  `xml:"name,attr"`
+	ClassName string  `xml:"classname,attr"`
+	Time      float64 `xml:"time,attr"`
+
+	// Properties carries arbitrary named metrics (e.g. a benchmark test's throughput and
+	// latency percentiles), rendered as child <property> elements rather than attributes
+	// so they survive a consumer that only understands the JUnit schema loosely.
+	Properties *properties `xml:"properties,omitempty"`
+
+	Skipped *skipped   `xml:"skipped,omitempty"`
+	Failure *failure   `xml:"failure,omitempty"`
+	Error   *testError `xml:"error,omitempty"`
+
+	SystemOut string `xml:"system-out,omitempty"`
+	SystemErr string `xml:"system-err,omitempty"`
+}
+
+type skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Details string `xml:",chardata"`
+}
+
+type testError struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Details string `xml:",chardata"`
+}
+
+// testCaseProperties renders a TestResult's Iterations and Metrics (populated by e.g. the
+// benchmark runner) as <properties>, sorting metric names for stable output. Returns nil
+// for a result with neither, so most test cases render without an empty <properties/>.
+func testCaseProperties(r testrunner.TestResult) *properties {
+	if r.Iterations == 0 && len(r.Metrics) == 0 {
+		return nil
+	}
+
+	var props []property
+	if r.Iterations > 0 {
+		props = append(props, property{Name: "iterations", Value: strconv.Itoa(r.Iterations)})
+	}
+
+	names := make([]string, 0, len(r.Metrics))
+	for name := range r.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		props = append(props, property{Name: name, Value: strconv.FormatFloat(r.Metrics[name], 'f', -1, 64)})
+	}
 
-	Error   string `xml:"error,omitempty"`
-	Failure string `xml:"failure,omitempty"`
+	return &properties{Property: props}
+}
+
+// testTypeTally accumulates the test-type-level counters reported on each top-level
+// <testsuite>, so they reflect just that test type's own results rather than the run's
+// grand total.
+type testTypeTally struct {
+	tests, failures, errors, skipped int
 }
 
 func reportXUnitFormat(results []testrunner.TestResult) (string, error) {
 	// test type => package => data stream => test cases
 	tests := map[string]map[string]map[string][]testCase{}
+	tallies := map[string]*testTypeTally{}
+	// package => data stream => package version, used to populate the data stream suite's
+	// <properties> alongside package/data_stream/test_type.
+	versions := map[string]map[string]string{}
 
-	var numTests, numFailures, numErrors int
 	for _, r := range results {
 		testType := string(r.TestType)
 		if _, exists := tests[testType]; !exists {
 			tests[testType] = map[string]map[string][]testCase{}
+			tallies[testType] = &testTypeTally{}
 		}
 
 		if _, exists := tests[testType][r.Package]; !exists {
 			tests[testType][r.Package] = map[string][]testCase{}
+			versions[r.Package] = map[string]string{}
 		}
 
 		if _, exists := tests[testType][r.Package][r.DataStream]; !exists {
 			tests[testType][r.Package][r.DataStream] = make([]testCase, 0)
 		}
-
-		var failure string
-		if r.FailureMsg != "" {
-			failure = r.FailureMsg
-			numFailures++
-		}
-
-		if r.FailureDetails != "" {
-			failure += ": " + r.FailureDetails
+		if r.Version != "" {
+			versions[r.Package][r.DataStream] = r.Version
 		}
 
-		if r.ErrorMsg != "" {
-			numErrors++
+		c := testCase{
+			Name:       r.Name,
+			ClassName:  fmt.Sprintf("%s.%s.%s", testType, r.Package, r.DataStream),
+			Time:       r.TimeElapsed.Seconds(),
+			SystemOut:  r.Stdout,
+			SystemErr:  r.Stderr,
+			Properties: testCaseProperties(r),
 		}
 
-		c := testCase{
-			Name:    r.Name,
-			Time:    r.TimeElapsed,
-			Error:   r.ErrorMsg,
-			Failure: failure,
+		tally := tallies[testType]
+		switch {
+		case r.Skipped:
+			tally.skipped++
+			c.Skipped = &skipped{Message: r.SkipReason}
+		case r.ErrorMsg != "":
+			tally.errors++
+			c.Error = &testError{
+				Message: r.ErrorMsg,
+				Type:    "error",
+				Details: r.ErrorMsg,
+			}
+		case r.FailureMsg != "":
+			tally.failures++
+			c.Failure = &failure{
+				Message: r.FailureMsg,
+				Type:    "failure",
+				Details: r.FailureDetails,
+			}
 		}
-		numTests++
 
+		tally.tests++
 		tests[testType][r.Package][r.DataStream] = append(tests[testType][r.Package][r.DataStream], c)
 	}
 
+	hostname, err := osHostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	timestamp := timeNow().UTC().Format(time.RFC3339)
+
 	var ts testSuites
 	ts.Suites = make([]testSuite, 0)
 
 	for testType, packages := range tests {
+		tally := tallies[testType]
 		testTypeSuite := testSuite{
 			Comment: fmt.Sprintf("test suite for %s tests", testType),
 			Name:    testType,
 
-			NumTests:    numTests,
-			NumFailures: numFailures,
-			NumErrors:   numErrors,
+			Timestamp: timestamp,
+			Hostname:  hostname,
+
+			NumTests:    tally.tests,
+			NumFailures: tally.failures,
+			NumErrors:   tally.errors,
+			NumSkipped:  tally.skipped,
 
 			Suites: make([]testSuite, 0),
 		}
 
 		for pkgName, pkg := range packages {
 			pkgSuite := testSuite{
-				Name:    pkgName,
-				Comment: fmt.Sprintf("test suite for package: %s", pkgName),
-				Suites:  make([]testSuite, 0),
+				Name:      pkgName,
+				Comment:   fmt.Sprintf("test suite for package: %s", pkgName),
+				Timestamp: timestamp,
+				Hostname:  hostname,
+				Suites:    make([]testSuite, 0),
 			}
 
 			for dsName, ds := range pkg {
+				var dsTime float64
+				var dsFailures, dsErrors, dsSkipped int
+				for _, c := range ds {
+					dsTime += c.Time
+					if c.Failure != nil {
+						dsFailures++
+					}
+					if c.Error != nil {
+						dsErrors++
+					}
+					if c.Skipped != nil {
+						dsSkipped++
+					}
+				}
+
 				dsSuite := testSuite{
-					Name:    dsName,
-					Comment: fmt.Sprintf("test suite for data stream: %s", dsName),
-					Cases:   ds,
+					Name:      dsName,
+					Comment:   fmt.Sprintf("test suite for data stream: %s", dsName),
+					Timestamp: timestamp,
+					Hostname:  hostname,
+					Properties: &properties{
+						Property: []property{
+							{Name: "package", Value: pkgName},
+							{Name: "data_stream", Value: dsName},
+							{Name: "test_type", Value: testType},
+							{Name: "version", Value: versions[pkgName][dsName]},
+						},
+					},
+					NumTests:    len(ds),
+					NumFailures: dsFailures,
+					NumErrors:   dsErrors,
+					NumSkipped:  dsSkipped,
+					Time:        dsTime,
+					Cases:       ds,
 				}
 
 				pkgSuite.Suites = append(pkgSuite.Suites, dsSuite)
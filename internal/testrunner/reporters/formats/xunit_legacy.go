@@ -0,0 +1,131 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package formats
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+// legacyTestSuites, legacyTestSuite and legacyTestCase preserve the original, non-standard
+// xUnit layout (failures/errors rendered as text attributes instead of child elements) for
+// consumers that depend on it. See ReportFormatXUnit for the JUnit-compatible replacement.
+type legacyTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []legacyTestSuite `xml:"testsuite"`
+}
+type legacyTestSuite struct {
+	Comment string `xml:",comment"`
+
+	Name        string `xml:"name,attr"`
+	NumTests    int    `xml:"tests,attr,omitempty"`
+	NumFailures int    `xml:"failures,attr,omitempty"`
+	NumErrors   int    `xml:"errors,attr,omitempty"`
+
+	Suites []legacyTestSuite `xml:"testsuite,omitempty"`
+	Cases  []legacyTestCase  `xml:"testcase,omitempty"`
+}
+type legacyTestCase struct {
+	Name      string `xml:"name,attr"`
+	ClassName string `xml:"classname,attr"`
+	Time      string `xml:"time,attr"`
+
+	Error   string `xml:"error,attr,omitempty"`
+	Failure string `xml:"failure,attr,omitempty"`
+}
+
+func reportXUnitLegacyFormat(results []testrunner.TestResult) (string, error) {
+	// test type => package => data stream => test cases
+	tests := map[string]map[string]map[string][]legacyTestCase{}
+
+	var numTests, numFailures, numErrors int
+	for _, r := range results {
+		testType := string(r.TestType)
+		if _, exists := tests[testType]; !exists {
+			tests[testType] = map[string]map[string][]legacyTestCase{}
+		}
+
+		if _, exists := tests[testType][r.Package]; !exists {
+			tests[testType][r.Package] = map[string][]legacyTestCase{}
+		}
+
+		if _, exists := tests[testType][r.Package][r.DataStream]; !exists {
+			tests[testType][r.Package][r.DataStream] = make([]legacyTestCase, 0)
+		}
+
+		var failure string
+		if r.FailureMsg != "" {
+			failure = r.FailureMsg
+			numFailures++
+		}
+
+		if r.FailureDetails != "" {
+			failure += ": " + r.FailureDetails
+		}
+
+		if r.ErrorMsg != "" {
+			numErrors++
+		}
+
+		c := legacyTestCase{
+			Name:    r.Name,
+			Time:    r.TimeElapsed.String(),
+			Error:   r.ErrorMsg,
+			Failure: failure,
+		}
+		numTests++
+
+		tests[testType][r.Package][r.DataStream] = append(tests[testType][r.Package][r.DataStream], c)
+	}
+
+	var ts legacyTestSuites
+	ts.Suites = make([]legacyTestSuite, 0)
+
+	for testType, packages := range tests {
+		testTypeSuite := legacyTestSuite{
+			Comment: fmt.Sprintf("test suite for %s tests", testType),
+			Name:    testType,
+
+			NumTests:    numTests,
+			NumFailures: numFailures,
+			NumErrors:   numErrors,
+
+			Suites: make([]legacyTestSuite, 0),
+		}
+
+		for pkgName, pkg := range packages {
+			pkgSuite := legacyTestSuite{
+				Name:    pkgName,
+				Comment: fmt.Sprintf("test suite for package: %s", pkgName),
+				Suites:  make([]legacyTestSuite, 0),
+			}
+
+			for dsName, ds := range pkg {
+				dsSuite := legacyTestSuite{
+					Name:    dsName,
+					Comment: fmt.Sprintf("test suite for data stream: %s", dsName),
+					Cases:   ds,
+				}
+
+				pkgSuite.Suites = append(pkgSuite.Suites, dsSuite)
+			}
+
+			testTypeSuite.Suites = append(testTypeSuite.Suites, pkgSuite)
+		}
+
+		ts.Suites = append(ts.Suites, testTypeSuite)
+	}
+
+	out, err := xml.MarshalIndent(&ts, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to format test results as xUnit")
+	}
+
+	return xml.Header + string(out), nil
+}
@@ -0,0 +1,165 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package formats
+
+import (
+	"encoding/xml"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// TestReportXUnitFormat diffs the generated report against a golden file covering the shape
+// elastic-package's xUnit output is expected to have: failures and errors as child elements
+// (not attributes, unlike ReportFormatXUnitLegacy), an optional skipped child, system-out/
+// system-err captures, and a time attribute expressed in seconds rather than as a Go duration
+// string. This is a string/golden-file comparison, not validation against the actual Jenkins
+// JUnit XSD.
+func TestReportXUnitFormat(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2020, 10, 21, 10, 0, 0, 0, time.UTC) }
+	osHostname = func() (string, error) { return "test-runner", nil }
+	defer func() {
+		timeNow = time.Now
+		osHostname = os.Hostname
+	}()
+
+	results := []testrunner.TestResult{
+		{
+			TestType:    "pipeline",
+			Package:     "apache",
+			DataStream:  "access",
+			Name:        "test for access.log",
+			TimeElapsed: 1200 * time.Millisecond,
+			Stdout:      "simulated pipeline output",
+		},
+		{
+			TestType:       "pipeline",
+			Package:        "apache",
+			DataStream:     "access",
+			Name:           "test for error.log",
+			TimeElapsed:    250 * time.Millisecond,
+			FailureMsg:     "fields did not match",
+			FailureDetails: `expected.geo.city_name: got "", want "Paris"`,
+		},
+		{
+			TestType:   "pipeline",
+			Package:    "apache",
+			DataStream: "access",
+			Name:       "test for skipped.log",
+			Skipped:    true,
+			SkipReason: "fixture pending review",
+		},
+	}
+
+	report, err := reportXUnitFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "xunit.golden.xml")
+	if *update {
+		if err := ioutil.WriteFile(golden, []byte(report), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := pretty.Compare(strings.TrimSpace(string(expected)), strings.TrimSpace(report)); diff != "" {
+		t.Errorf("report doesn't match golden file %s (-want +got):\n%s", golden, diff)
+	}
+}
+
+// TestReportXUnitFormatPerTestTypeCounters guards against the top-level <testsuite> for one
+// test type reporting another test type's tests/failures, by running one passing "pipeline"
+// result alongside one failing "benchmark" result and asserting each suite only counts its
+// own.
+func TestReportXUnitFormatPerTestTypeCounters(t *testing.T) {
+	results := []testrunner.TestResult{
+		{
+			TestType:   "pipeline",
+			Package:    "apache",
+			DataStream: "access",
+			Name:       "test for access.log",
+		},
+		{
+			TestType:   "benchmark",
+			Package:    "apache",
+			DataStream: "access",
+			Name:       "benchmark for access.log",
+			FailureMsg: "latency regressed",
+		},
+	}
+
+	report, err := reportXUnitFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed testSuites
+	if err := xml.Unmarshal([]byte(report), &parsed); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, report)
+	}
+
+	if len(parsed.Suites) != 2 {
+		t.Fatalf("expected 2 top-level test suites, got %d", len(parsed.Suites))
+	}
+
+	for _, suite := range parsed.Suites {
+		if suite.NumTests != 1 {
+			t.Errorf("expected suite %s to report 1 test, got %d", suite.Name, suite.NumTests)
+		}
+
+		switch suite.Name {
+		case "pipeline":
+			if suite.NumFailures != 0 {
+				t.Errorf("expected pipeline suite to report 0 failures, got %d", suite.NumFailures)
+			}
+		case "benchmark":
+			if suite.NumFailures != 1 {
+				t.Errorf("expected benchmark suite to report 1 failure, got %d", suite.NumFailures)
+			}
+		default:
+			t.Errorf("unexpected suite name: %s", suite.Name)
+		}
+	}
+}
+
+func TestReportXUnitLegacyFormat(t *testing.T) {
+	results := []testrunner.TestResult{
+		{
+			TestType:    "pipeline",
+			Package:     "apache",
+			DataStream:  "access",
+			Name:        "test for error.log",
+			TimeElapsed: 250 * time.Millisecond,
+			FailureMsg:  "fields did not match",
+		},
+	}
+
+	report, err := reportXUnitLegacyFormat(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report, `failure="fields did not match"`) {
+		t.Errorf("expected legacy report to contain failure attribute, got: %s", report)
+	}
+	if !strings.Contains(report, `time="250ms"`) {
+		t.Errorf("expected legacy report to render time as a Go duration string, got: %s", report)
+	}
+}
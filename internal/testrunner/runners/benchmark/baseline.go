@@ -0,0 +1,99 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+// Baseline holds previously recorded metrics for benchmark fixtures, keyed by fixture name
+// then metric name, loaded from the file passed via --baseline.
+type Baseline map[string]map[string]float64
+
+// LoadBaseline reads a Baseline previously written by WriteBaseline.
+func LoadBaseline(path string) (Baseline, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading baseline failed (path: %s)", path)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling baseline failed (path: %s)", path)
+	}
+	return baseline, nil
+}
+
+// WriteBaseline records results as a Baseline that a later run can be compared against.
+func WriteBaseline(path string, results []testrunner.TestResult) error {
+	baseline := make(Baseline, len(results))
+	for _, r := range results {
+		baseline[r.Name] = r.Metrics
+	}
+
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling baseline failed")
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// latencyMetrics regress when they go up relative to the baseline; every other metric (e.g.
+// throughput, bytes transferred) is assumed to regress when it goes down.
+var latencyMetrics = map[string]bool{
+	"latency_p50_ms": true,
+	"latency_p95_ms": true,
+	"latency_p99_ms": true,
+}
+
+// CheckRegression compares metrics for a fixture against its recorded baseline, returning an
+// error describing the first metric that regressed by more than threshold (e.g. 0.1 for a
+// 10% regression budget). A fixture or metric missing from the baseline is skipped rather
+// than treated as a regression, so --baseline works against a baseline recorded before the
+// fixture existed. Metric names are checked in sorted order so that, when more than one
+// metric regresses, the reported metric is deterministic across identical runs rather than
+// depending on Go's randomized map iteration order.
+func (b Baseline) CheckRegression(fixtureName string, metrics map[string]float64, threshold float64) error {
+	if threshold <= 0 {
+		return nil
+	}
+
+	base, tracked := b[fixtureName]
+	if !tracked {
+		return nil
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := metrics[name]
+		baseValue, tracked := base[name]
+		if !tracked || baseValue == 0 {
+			continue
+		}
+
+		delta := (value - baseValue) / baseValue
+		regressed := delta
+		if !latencyMetrics[name] {
+			regressed = -delta
+		}
+
+		if regressed > threshold {
+			return fmt.Errorf("%s regressed by %.1f%% (baseline: %.2f, got: %.2f)", name, regressed*100, baseValue, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package benchmark
+
+import "testing"
+
+func TestBaselineCheckRegressionLatency(t *testing.T) {
+	baseline := Baseline{
+		"events.json": {"latency_p95_ms": 10},
+	}
+
+	if err := baseline.CheckRegression("events.json", map[string]float64{"latency_p95_ms": 10.5}, 0.1); err != nil {
+		t.Errorf("expected a 5%% latency increase to stay within a 10%% threshold, got: %v", err)
+	}
+
+	err := baseline.CheckRegression("events.json", map[string]float64{"latency_p95_ms": 12}, 0.1)
+	if err == nil {
+		t.Fatal("expected a 20% latency increase to exceed a 10% threshold")
+	}
+}
+
+func TestBaselineCheckRegressionThroughput(t *testing.T) {
+	baseline := Baseline{
+		"events.json": {"throughput_eps": 1000},
+	}
+
+	err := baseline.CheckRegression("events.json", map[string]float64{"throughput_eps": 850}, 0.1)
+	if err == nil {
+		t.Fatal("expected a 15% throughput drop to exceed a 10% threshold")
+	}
+}
+
+func TestBaselineCheckRegressionUnknownFixtureSkipped(t *testing.T) {
+	baseline := Baseline{
+		"other.json": {"latency_p95_ms": 10},
+	}
+
+	if err := baseline.CheckRegression("events.json", map[string]float64{"latency_p95_ms": 1000}, 0.1); err != nil {
+		t.Errorf("expected a fixture missing from the baseline to be skipped, got: %v", err)
+	}
+}
+
+func TestBaselineCheckRegressionDisabledThreshold(t *testing.T) {
+	baseline := Baseline{
+		"events.json": {"latency_p95_ms": 10},
+	}
+
+	if err := baseline.CheckRegression("events.json", map[string]float64{"latency_p95_ms": 1000}, 0); err != nil {
+		t.Errorf("expected a zero threshold to disable regression checks, got: %v", err)
+	}
+}
+
+// TestBaselineCheckRegressionDeterministicOrder guards against reporting a different metric
+// as "the" regression on different runs when more than one metric regresses: the result must
+// always name the alphabetically-first regressed metric, not whichever one a random map
+// iteration visited first.
+func TestBaselineCheckRegressionDeterministicOrder(t *testing.T) {
+	baseline := Baseline{
+		"events.json": {
+			"latency_p95_ms": 10,
+			"latency_p99_ms": 10,
+			"throughput_eps": 1000,
+		},
+	}
+
+	metrics := map[string]float64{
+		"latency_p95_ms": 20,
+		"latency_p99_ms": 20,
+		"throughput_eps": 500,
+	}
+
+	for i := 0; i < 10; i++ {
+		err := baseline.CheckRegression("events.json", metrics, 0.1)
+		if err == nil {
+			t.Fatal("expected a regression error")
+		}
+		if err.Error() != "latency_p95_ms regressed by 100.0% (baseline: 10.00, got: 20.00)" {
+			t.Fatalf("expected the alphabetically-first regressed metric to be reported, got: %v", err)
+		}
+	}
+}
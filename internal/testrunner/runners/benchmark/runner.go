@@ -0,0 +1,275 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/go-elasticsearch/v7"
+
+	"github.com/elastic/elastic-package/internal/logger"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/testrunner"
+	"github.com/elastic/elastic-package/internal/testrunner/runners/pipeline"
+)
+
+const (
+	// TestType defining benchmark tests
+	TestType testrunner.TestType = "benchmark"
+
+	// defaultIterations is used when TestOptions.NumIterations is unset or non-positive.
+	defaultIterations = 100
+)
+
+// Runner repeatedly simulates an ingest pipeline over a fixture to measure throughput and
+// latency. It reuses the pipeline runner's install/uninstall machinery rather than
+// reimplementing it, since a benchmark exercises the exact same pipelines a pipeline test
+// does.
+type Runner struct {
+	// ESClient is the Elasticsearch client used to install pipelines and simulate ingestion.
+	ESClient *elasticsearch.Client
+
+	// FS abstracts reading benchmark fixtures, defaulting to the local filesystem.
+	FS testrunner.FS
+}
+
+// NewRunner creates a benchmark Runner backed by the local filesystem.
+func NewRunner(esClient *elasticsearch.Client) *Runner {
+	return &Runner{
+		ESClient: esClient,
+		FS:       testrunner.OSFS{},
+	}
+}
+
+func init() {
+	testrunner.RegisterRunner(TestType, func(ctx context.Context, options testrunner.TestOptions, filter testrunner.Filter) ([]testrunner.TestResult, error) {
+		return NewRunner(options.ESClient).Run(ctx, options, filter)
+	})
+}
+
+// Run runs the benchmark fixtures defined under the given folder that match filter, failing
+// fast if a fixture errors or regresses against options.BaselinePath.
+func (r *Runner) Run(ctx context.Context, options testrunner.TestOptions, filter testrunner.Filter) ([]testrunner.TestResult, error) {
+	fixtureFiles, err := r.listFixtureFiles(options.TestFolder.Path, options.TestFolder.Package, options.TestFolder.DataStream, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing benchmark fixtures failed")
+	}
+
+	dataStreamPath, found, err := packages.FindDataStreamRootForPath(options.TestFolder.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "locating data_stream root failed")
+	}
+	if !found {
+		return nil, errors.New("data stream root not found")
+	}
+
+	entryPipeline, pipelineIDs, err := pipeline.InstallIngestPipelines(r.ESClient, dataStreamPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "installing ingest pipelines failed")
+	}
+	defer func() {
+		if err := pipeline.UninstallIngestPipelines(r.ESClient, pipelineIDs); err != nil {
+			logger.Warnf("uninstalling ingest pipelines failed: %v", err)
+		}
+	}()
+
+	iterations := options.NumIterations
+	if iterations < 1 {
+		iterations = defaultIterations
+	}
+
+	var baseline Baseline
+	if options.BaselinePath != "" {
+		baseline, err = LoadBaseline(options.BaselinePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading benchmark baseline failed")
+		}
+	}
+
+	var results []testrunner.TestResult
+	for _, fixtureFile := range fixtureFiles {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		tr, err := r.runFixture(ctx, options, entryPipeline, fixtureFile, iterations, baseline)
+		results = append(results, tr)
+		if err != nil {
+			return results, errors.New("at least one benchmark failed or regressed")
+		}
+	}
+	return results, nil
+}
+
+func (r *Runner) listFixtureFiles(path, pkg, dataStream string, filter testrunner.Filter) ([]string, error) {
+	fis, err := r.FS.ReadDir(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading benchmark fixtures failed (path: %s)", path)
+	}
+
+	var files []string
+	for _, fi := range fis {
+		if filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+
+		name := strings.TrimSuffix(fi.Name(), filepath.Ext(fi.Name()))
+		if !filter.Match(pkg, dataStream, name) {
+			continue
+		}
+
+		files = append(files, fi.Name())
+	}
+	return files, nil
+}
+
+func (r *Runner) runFixture(ctx context.Context, options testrunner.TestOptions, entryPipeline, fixtureFile string, iterations int, baseline Baseline) (testrunner.TestResult, error) {
+	tr := testrunner.TestResult{
+		TestType:   TestType,
+		Package:    options.TestFolder.Package,
+		DataStream: options.TestFolder.DataStream,
+		Name:       fixtureFile,
+		Iterations: iterations,
+		Version:    options.PackageVersion,
+	}
+	startTime := time.Now()
+
+	fixturePath := filepath.Join(options.TestFolder.Path, fixtureFile)
+	raw, err := r.FS.ReadFile(fixturePath)
+	if err != nil {
+		err = errors.Wrapf(err, "reading benchmark fixture failed (path: %s)", fixturePath)
+		tr.ErrorMsg = err.Error()
+		return tr, err
+	}
+
+	var events []json.RawMessage
+	if err := json.Unmarshal(raw, &events); err != nil {
+		err = errors.Wrapf(err, "unmarshalling benchmark fixture failed (path: %s)", fixturePath)
+		tr.ErrorMsg = err.Error()
+		return tr, err
+	}
+
+	body, err := simulateRequestBody(events)
+	if err != nil {
+		err = errors.Wrap(err, "building simulate request body failed")
+		tr.ErrorMsg = err.Error()
+		return tr, err
+	}
+
+	latencies := make([]time.Duration, 0, iterations)
+	var bytesIn, bytesOut int64
+	for i := 0; i < iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			tr.ErrorMsg = err.Error()
+			return tr, err
+		}
+
+		latency, respSize, err := r.simulateOnce(ctx, entryPipeline, body)
+		if err != nil {
+			err = errors.Wrapf(err, "simulating pipeline processing failed (iteration: %d)", i)
+			tr.ErrorMsg = err.Error()
+			return tr, err
+		}
+
+		latencies = append(latencies, latency)
+		bytesIn += int64(len(body))
+		bytesOut += respSize
+	}
+
+	tr.TimeElapsed = time.Since(startTime)
+	tr.Metrics = computeMetrics(len(events), iterations, latencies, bytesIn, bytesOut, tr.TimeElapsed)
+
+	if baseline != nil {
+		if err := baseline.CheckRegression(tr.Name, tr.Metrics, options.RegressionThreshold); err != nil {
+			tr.FailureMsg = err.Error()
+			return tr, errBenchmarkRegressed
+		}
+	}
+	return tr, nil
+}
+
+// errBenchmarkRegressed is returned by runFixture when CheckRegression finds the fixture's
+// metrics regressed past the configured threshold; tr.FailureMsg already carries the detail.
+var errBenchmarkRegressed = fmt.Errorf("benchmark regressed against baseline")
+
+type simulateDoc struct {
+	Source json.RawMessage `json:"_source"`
+}
+
+type simulateRequest struct {
+	Docs []simulateDoc `json:"docs"`
+}
+
+func simulateRequestBody(events []json.RawMessage) ([]byte, error) {
+	docs := make([]simulateDoc, len(events))
+	for i, event := range events {
+		docs[i] = simulateDoc{Source: event}
+	}
+	return json.Marshal(simulateRequest{Docs: docs})
+}
+
+// simulateOnce runs the pipeline simulate API once over body and returns how long it took
+// and how many bytes the response contained. Cancelling ctx (e.g. on SIGINT) aborts the
+// in-flight HTTP request rather than waiting for it to complete.
+func (r *Runner) simulateOnce(ctx context.Context, pipelineID string, body []byte) (time.Duration, int64, error) {
+	start := time.Now()
+	resp, err := r.ESClient.Ingest.Simulate(bytes.NewReader(body),
+		r.ESClient.Ingest.Simulate.WithPipelineID(pipelineID),
+		r.ESClient.Ingest.Simulate.WithContext(ctx))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	elapsed := time.Since(start)
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "reading simulate response failed")
+	}
+	if resp.IsError() {
+		return 0, 0, fmt.Errorf("simulate request failed: %s", respBody)
+	}
+	return elapsed, int64(len(respBody)), nil
+}
+
+// computeMetrics summarizes a benchmark run as throughput, latency percentiles and total
+// bytes transferred, in the shape reporters surface as TestResult.Metrics.
+func computeMetrics(eventsPerIteration, iterations int, latencies []time.Duration, bytesIn, bytesOut int64, elapsed time.Duration) map[string]float64 {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	totalEvents := float64(eventsPerIteration * iterations)
+	return map[string]float64{
+		"throughput_eps": totalEvents / elapsed.Seconds(),
+		"latency_p50_ms": latencyPercentileMs(sorted, 0.50),
+		"latency_p95_ms": latencyPercentileMs(sorted, 0.95),
+		"latency_p99_ms": latencyPercentileMs(sorted, 0.99),
+		"bytes_in":       float64(bytesIn),
+		"bytes_out":      float64(bytesOut),
+	}
+}
+
+func latencyPercentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := int(p * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return float64(sorted[idx].Microseconds()) / 1000.0
+}
@@ -0,0 +1,23 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"github.com/elastic/go-elasticsearch/v7"
+)
+
+// InstallIngestPipelines installs the ingest pipelines defined for the data stream at
+// dataStreamPath and returns the entry pipeline ID together with the IDs of every pipeline
+// that was installed. It's exported so other test runners that exercise the same pipelines
+// (e.g. the benchmark runner) don't have to reimplement pipeline install/uninstall.
+func InstallIngestPipelines(esClient *elasticsearch.Client, dataStreamPath string) (string, []string, error) {
+	return installIngestPipelines(esClient, dataStreamPath)
+}
+
+// UninstallIngestPipelines removes the ingest pipelines previously installed by
+// InstallIngestPipelines.
+func UninstallIngestPipelines(esClient *elasticsearch.Client, pipelineIDs []string) error {
+	return uninstallIngestPipelines(esClient, pipelineIDs)
+}
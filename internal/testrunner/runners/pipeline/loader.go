@@ -0,0 +1,156 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// testCaseLoader parses the contents of a test case fixture file into a testCase.
+// testCasePath is the path of the fixture with any trailing .gz stripped (used to locate a
+// sibling config file, if any) and testCaseData is its (already decompressed) contents.
+type testCaseLoader func(testCasePath string, testCaseData []byte) (*testCase, error)
+
+var testCaseLoaders = map[string]testCaseLoader{}
+
+// registerLoader registers a testCaseLoader for the given file extension (including the
+// leading dot, e.g. ".yaml"). This lets community packages plug in additional fixture
+// formats (e.g. PCAP-to-events, CEF) without having to change the runner itself.
+func registerLoader(ext string, loader testCaseLoader) {
+	testCaseLoaders[ext] = loader
+}
+
+func init() {
+	registerLoader(".json", loadEventsTestCase)
+	registerLoader(".log", loadRawInputTestCase)
+	registerLoader(".yaml", loadYAMLTestCase)
+	registerLoader(".yml", loadYAMLTestCase)
+	registerLoader(".ndjson", loadNDJSONTestCase)
+}
+
+// testCaseExtension returns the extension a test case fixture file name is registered
+// under, stripping a trailing .gz first so a gzipped fixture (e.g. events.ndjson.gz) is
+// matched against the extension of its uncompressed form (e.g. .ndjson).
+func testCaseExtension(fileName string) string {
+	return filepath.Ext(strings.TrimSuffix(fileName, ".gz"))
+}
+
+// loadTestCaseFile decompresses testCaseData if testCasePath ends in .gz, then dispatches
+// it to the testCaseLoader registered for its extension. The .gz suffix is stripped from
+// testCasePath before it reaches the loader, so a loader reading a sibling config file or
+// deriving the test case name from the file name (e.g. loadRawInputTestCase) sees the
+// decompressed form (foo.log), not foo.log.gz.
+func loadTestCaseFile(testCasePath string, testCaseData []byte) (*testCase, error) {
+	if strings.HasSuffix(testCasePath, ".gz") {
+		var err error
+		testCaseData, err = gunzipTestCaseData(testCaseData)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decompressing test case failed (testCasePath: %s)", testCasePath)
+		}
+		testCasePath = strings.TrimSuffix(testCasePath, ".gz")
+	}
+
+	ext := testCaseExtension(testCasePath)
+	loader, defined := testCaseLoaders[ext]
+	if !defined {
+		return nil, fmt.Errorf("unsupported extension for test case file (ext: %s)", ext)
+	}
+	return loader(testCasePath, testCaseData)
+}
+
+func loadEventsTestCase(testCasePath string, testCaseData []byte) (*testCase, error) {
+	return createTestCaseForEvents(filepath.Base(testCasePath), testCaseData)
+}
+
+// createTestCaseForEventsWithConfig builds a testCase from inline events the same way
+// createTestCaseForEvents does, then overlays config onto it — the same config a .log
+// fixture reads from a sibling *-config.yml via readConfigForTestCase (a .json fixture
+// carries no config), but inlined for a self-contained YAML fixture.
+func createTestCaseForEventsWithConfig(name string, events []byte, config testConfig) (*testCase, error) {
+	tc, err := createTestCaseForEvents(name, events)
+	if err != nil {
+		return nil, err
+	}
+	tc.config = config
+	return tc, nil
+}
+
+func loadRawInputTestCase(testCasePath string, testCaseData []byte) (*testCase, error) {
+	config, err := readConfigForTestCase(testCasePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading config for test case failed")
+	}
+	return createTestCaseForRawInput(filepath.Base(testCasePath), testCaseData, config)
+}
+
+// yamlTestCase is the shape of a .yaml/.yml test case fixture: an inline list of events,
+// as in a .json fixture, plus an optional inline config, which a .log fixture reads from a
+// sibling *-config.yml file; both are self-contained in a single document.
+type yamlTestCase struct {
+	Events []map[string]interface{} `yaml:"events"`
+	Config testConfig               `yaml:"config"`
+}
+
+func loadYAMLTestCase(testCasePath string, testCaseData []byte) (*testCase, error) {
+	var def yamlTestCase
+	if err := yaml.Unmarshal(testCaseData, &def); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling YAML test case failed")
+	}
+
+	events, err := json.Marshal(def.Events)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting YAML test case events to JSON failed")
+	}
+	return createTestCaseForEventsWithConfig(filepath.Base(testCasePath), events, def.Config)
+}
+
+// loadNDJSONTestCase reads one JSON event per line and feeds the resulting array to the
+// same code path as a .json fixture.
+func loadNDJSONTestCase(testCasePath string, testCaseData []byte) (*testCase, error) {
+	var events []json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(testCaseData))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		events = append(events, append(json.RawMessage(nil), line...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading NDJSON test case failed")
+	}
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting NDJSON test case events to JSON failed")
+	}
+	return createTestCaseForEvents(filepath.Base(testCasePath), data)
+}
+
+// gunzipTestCaseData transparently decompresses gzipped fixtures (e.g. *.log.gz,
+// *.ndjson.gz) so the rest of the loading pipeline never has to know about compression.
+func gunzipTestCaseData(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing gzip reader failed")
+	}
+	defer zr.Close()
+
+	uncompressed, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompressing gzip data failed")
+	}
+	return uncompressed, nil
+}
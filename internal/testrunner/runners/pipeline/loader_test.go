@@ -0,0 +1,163 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTestCaseExtension(t *testing.T) {
+	tests := []struct {
+		fileName string
+		expected string
+	}{
+		{"test-case.yaml", ".yaml"},
+		{"test-case.yml", ".yml"},
+		{"test-case.ndjson", ".ndjson"},
+		{"test-case.log", ".log"},
+		{"test-case.log.gz", ".log"},
+		{"test-case.ndjson.gz", ".ndjson"},
+		{"test-case.json", ".json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			if ext := testCaseExtension(tt.fileName); ext != tt.expected {
+				t.Errorf("expected extension %s, got %s", tt.expected, ext)
+			}
+		})
+	}
+}
+
+// TestLoadTestCaseFileStripsGzSuffixBeforeDispatch guards against a loader seeing a
+// .gz-suffixed path: that breaks sibling *-config.yml lookups (readConfigForTestCase) and
+// bakes ".gz" into the reported test case name, both of which are derived from the path
+// handed to the loader, not from the original file on disk.
+func TestLoadTestCaseFileStripsGzSuffixBeforeDispatch(t *testing.T) {
+	const spyExt = ".spy"
+	var seenPath string
+	registerLoader(spyExt, func(testCasePath string, testCaseData []byte) (*testCase, error) {
+		seenPath = testCasePath
+		return &testCase{name: testCasePath}, nil
+	})
+	defer delete(testCaseLoaders, spyExt)
+
+	tests := []struct {
+		name         string
+		testCasePath string
+		testCaseData []byte
+		wantPath     string
+	}{
+		{
+			name:         "uncompressed",
+			testCasePath: "test-case.spy",
+			testCaseData: []byte("raw"),
+			wantPath:     "test-case.spy",
+		},
+		{
+			name:         "gzip compressed",
+			testCasePath: "test-case.spy.gz",
+			testCaseData: gzipData(t, []byte("raw")),
+			wantPath:     "test-case.spy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seenPath = ""
+			if _, err := loadTestCaseFile(tt.testCasePath, tt.testCaseData); err != nil {
+				t.Fatal(err)
+			}
+			if seenPath != tt.wantPath {
+				t.Errorf("expected loader to see path %s, got %s", tt.wantPath, seenPath)
+			}
+		})
+	}
+}
+
+func TestLoadTestCaseFileUnsupportedExtension(t *testing.T) {
+	if _, err := loadTestCaseFile("test-case.unknown", []byte("raw")); err == nil {
+		t.Error("expected an error for an unregistered extension, got nil")
+	}
+}
+
+// TestLoadYAMLTestCaseEndToEnd loads a full .yaml fixture with inline events and config,
+// exercising the actual YAML-unmarshal-to-testCase path rather than just the extension/gz
+// plumbing around it.
+func TestLoadYAMLTestCaseEndToEnd(t *testing.T) {
+	data := []byte(`
+events:
+  - message: "first event"
+    field: "a"
+  - message: "second event"
+    field: "b"
+config:
+  numeric_keyword_fields:
+    - "field"
+`)
+
+	tc, err := loadYAMLTestCase("access-yaml.yaml", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.name != "access-yaml.yaml" {
+		t.Errorf("expected test case name access-yaml.yaml, got %s", tc.name)
+	}
+}
+
+// TestLoadNDJSONTestCaseEndToEnd loads a full .ndjson fixture with more than one event,
+// exercising the actual line-scanning-to-testCase path rather than just the extension/gz
+// plumbing around it.
+func TestLoadNDJSONTestCaseEndToEnd(t *testing.T) {
+	data := []byte("{\"message\":\"first event\"}\n{\"message\":\"second event\"}\n")
+
+	tc, err := loadNDJSONTestCase("access.ndjson", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.name != "access.ndjson" {
+		t.Errorf("expected test case name access.ndjson, got %s", tc.name)
+	}
+}
+
+// TestCreateTestCaseForEventsWithConfig guards the part of the inline-config YAML feature
+// that's easy to get wrong silently: the config parsed from the YAML fixture must actually
+// land on the resulting testCase, not just get parsed and discarded.
+func TestCreateTestCaseForEventsWithConfig(t *testing.T) {
+	var config testConfig
+	if err := yaml.Unmarshal([]byte("numeric_keyword_fields:\n  - field\n"), &config); err != nil {
+		t.Fatal(err)
+	}
+
+	tc, err := createTestCaseForEventsWithConfig("events-config.yaml", []byte(`[{"message":"hi"}]`), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.name != "events-config.yaml" {
+		t.Errorf("expected test case name events-config.yaml, got %s", tc.name)
+	}
+	if !reflect.DeepEqual(tc.config, config) {
+		t.Errorf("expected config to be wired onto the test case, got %+v, want %+v", tc.config, config)
+	}
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -5,14 +5,17 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/elastic/go-elasticsearch/v7"
+
 	"github.com/elastic/elastic-package/internal/logger"
 	"github.com/elastic/elastic-package/internal/packages"
 	"github.com/elastic/elastic-package/internal/testrunner"
@@ -23,23 +26,48 @@ const (
 	TestType testrunner.TestType = "pipeline"
 )
 
-type runner struct {
-	options testrunner.TestOptions
+// Runner runs the pipeline tests defined under a data stream's test folder. A Runner can
+// be reused across multiple Run calls, but a single Run call is not safe to invoke
+// concurrently from multiple goroutines.
+type Runner struct {
+	// ESClient is the Elasticsearch client used to install pipelines and simulate
+	// ingestion.
+	ESClient *elasticsearch.Client
+
+	// FS abstracts reading test case fixtures, defaulting to the local filesystem.
+	FS testrunner.FS
+
+	// Parallelism bounds how many test cases are simulated concurrently. Pipeline
+	// install/uninstall is always serialized once per data stream regardless of this
+	// setting. Values less than 1 are treated as 1 (sequential).
+	Parallelism int
 }
 
-// Run runs the pipeline tests defined under the given folder
-func Run(options testrunner.TestOptions) ([]testrunner.TestResult, error) {
-	r := runner{options}
-	return r.run()
+// NewRunner creates a pipeline test Runner backed by the local filesystem.
+func NewRunner(esClient *elasticsearch.Client) *Runner {
+	return &Runner{
+		ESClient: esClient,
+		FS:       testrunner.OSFS{},
+	}
 }
 
-func (r *runner) run() ([]testrunner.TestResult, error) {
-	testCaseFiles, err := r.listTestCaseFiles()
+func init() {
+	testrunner.RegisterRunner(TestType, func(ctx context.Context, options testrunner.TestOptions, filter testrunner.Filter) ([]testrunner.TestResult, error) {
+		return NewRunner(options.ESClient).Run(ctx, options, filter)
+	})
+}
+
+// Run runs the pipeline tests defined under the given folder that match filter. Cancelling
+// ctx (e.g. on SIGINT) stops launching new test cases, aborts in-flight simulate calls (ctx
+// is threaded through to simulatePipelineProcessing, which passes it to the underlying
+// Elasticsearch request), and still triggers the deferred uninstallIngestPipelines.
+func (r *Runner) Run(ctx context.Context, options testrunner.TestOptions, filter testrunner.Filter) ([]testrunner.TestResult, error) {
+	testCaseFiles, err := r.listTestCaseFiles(options.TestFolder.Path, options.TestFolder.Package, options.TestFolder.DataStream, filter)
 	if err != nil {
 		return nil, errors.Wrap(err, "listing test case definitions failed")
 	}
 
-	dataStreamPath, found, err := packages.FindDataStreamRootForPath(r.options.TestFolder.Path)
+	dataStreamPath, found, err := packages.FindDataStreamRootForPath(options.TestFolder.Path)
 	if err != nil {
 		return nil, errors.Wrap(err, "locating data_stream root failed")
 	}
@@ -47,67 +75,129 @@ func (r *runner) run() ([]testrunner.TestResult, error) {
 		return nil, errors.New("data stream root not found")
 	}
 
-	entryPipeline, pipelineIDs, err := installIngestPipelines(r.options.ESClient, dataStreamPath)
+	entryPipeline, pipelineIDs, err := installIngestPipelines(r.ESClient, dataStreamPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "installing ingest pipelines failed")
 	}
 	defer func() {
-		err := uninstallIngestPipelines(r.options.ESClient, pipelineIDs)
+		err := uninstallIngestPipelines(r.ESClient, pipelineIDs)
 		if err != nil {
 			logger.Warnf("uninstalling ingest pipelines failed: %v", err)
 		}
 	}()
 
-	var failed bool
-	results := make([]testrunner.TestResult, 0)
-	for _, testCaseFile := range testCaseFiles {
-		tr := testrunner.TestResult{
-			TestType:   TestType,
-			Package:    r.options.TestFolder.Package,
-			DataStream: r.options.TestFolder.DataStream,
-		}
-		startTime := time.Now()
+	results, failed := runConcurrently(ctx, testCaseFiles, r.Parallelism, func(i int, testCaseFile string) (testrunner.TestResult, error) {
+		return r.runTestCase(ctx, options, entryPipeline, testCaseFile)
+	})
 
-		tc, err := r.loadTestCaseFile(testCaseFile)
-		if err != nil {
-			err := errors.Wrap(err, "loading test case failed")
-			tr.ErrorMsg = err.Error()
-			return results, err
-		}
-		fmt.Printf("Test case: %s\n", tc.name)
-		tr.Name = tc.name
-		results = append(results, tr)
+	if ctx.Err() != nil {
+		return results, ctx.Err()
+	}
+	if failed {
+		return results, errors.New("at least one test case failed")
+	}
+	return results, nil
+}
 
-		result, err := simulatePipelineProcessing(r.options.ESClient, entryPipeline, tc)
-		if err != nil {
-			err := errors.Wrap(err, "simulating pipeline processing failed")
-			tr.ErrorMsg = err.Error()
-			return results, err
-		}
+// runConcurrently runs fn for each of items, at most parallelism at a time (values less
+// than 1 are treated as 1), stopping once ctx is cancelled. It returns only the results for
+// items that were actually scheduled: the tail of items left unscheduled by cancellation is
+// trimmed rather than padded with zero-value TestResults, which reporters would otherwise
+// render as silently-passed, unnamed test cases.
+func runConcurrently(ctx context.Context, items []string, parallelism int, fn func(i int, item string) (testrunner.TestResult, error)) ([]testrunner.TestResult, bool) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
 
-		tr.TimeTaken = time.Now().Sub(startTime)
-		err = r.verifyResults(testCaseFile, result)
-		if err == errTestCaseFailed {
-			failed = true
-			tr.FailureMsg = err.Error()
-			continue
+	results := make([]testrunner.TestResult, len(items))
+	sem := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	var failedMu sync.Mutex
+	var failed bool
+	scheduled := 0
+
+runLoop:
+	for i, item := range items {
+		// select picks at random when both cases are ready, so check ctx first.
+		if ctx.Err() != nil {
+			break
 		}
-		if err != nil {
-			return results, errors.Wrap(err, "verifying test result failed")
+		select {
+		case <-ctx.Done():
+			break runLoop
+		case sem <- struct{}{}:
 		}
+		scheduled = i + 1
+
+		wg.Add(1)
+		go func(i int, item string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			tr, err := fn(i, item)
+			results[i] = tr
+			if err != nil {
+				failedMu.Lock()
+				failed = true
+				failedMu.Unlock()
+			}
+		}(i, item)
 	}
+	wg.Wait()
 
-	if failed {
-		return results, errors.New("at least one test case failed")
+	return results[:scheduled], failed
+}
+
+func (r *Runner) runTestCase(ctx context.Context, options testrunner.TestOptions, entryPipeline, testCaseFile string) (testrunner.TestResult, error) {
+	tr := testrunner.TestResult{
+		TestType:   TestType,
+		Package:    options.TestFolder.Package,
+		DataStream: options.TestFolder.DataStream,
+		Version:    options.PackageVersion,
 	}
+	startTime := time.Now()
 
-	return results, nil
+	tc, err := r.loadTestCaseFile(options.TestFolder.Path, testCaseFile)
+	if err != nil {
+		err := errors.Wrap(err, "loading test case failed")
+		tr.ErrorMsg = err.Error()
+		return tr, err
+	}
+	fmt.Printf("Test case: %s\n", tc.name)
+	tr.Name = tc.name
+
+	if err := ctx.Err(); err != nil {
+		tr.ErrorMsg = err.Error()
+		return tr, err
+	}
+
+	result, err := simulatePipelineProcessing(ctx, r.ESClient, entryPipeline, tc)
+	if err != nil {
+		err := errors.Wrap(err, "simulating pipeline processing failed")
+		tr.ErrorMsg = err.Error()
+		return tr, err
+	}
+
+	tr.TimeElapsed = time.Now().Sub(startTime)
+	testCasePath := filepath.Join(options.TestFolder.Path, testCaseFile)
+	err = r.verifyResults(testCasePath, options.GenerateTestResult, result)
+	if err == errTestCaseFailed {
+		tr.FailureMsg = err.Error()
+		return tr, err
+	}
+	if err != nil {
+		err = errors.Wrap(err, "verifying test result failed")
+		tr.ErrorMsg = err.Error()
+		return tr, err
+	}
+	return tr, nil
 }
 
-func (r *runner) listTestCaseFiles() ([]string, error) {
-	fis, err := ioutil.ReadDir(r.options.TestFolder.Path)
+func (r *Runner) listTestCaseFiles(path, pkg, dataStream string, filter testrunner.Filter) ([]string, error) {
+	fis, err := r.FS.ReadDir(path)
 	if err != nil {
-		return nil, errors.Wrapf(err, "reading pipeline tests failed (path: %s)", r.options.TestFolder.Path)
+		return nil, errors.Wrapf(err, "reading pipeline tests failed (path: %s)", path)
 	}
 
 	var files []string
@@ -115,45 +205,34 @@ func (r *runner) listTestCaseFiles() ([]string, error) {
 		if strings.HasSuffix(fi.Name(), expectedTestResultSuffix) || strings.HasSuffix(fi.Name(), configTestSuffix) {
 			continue
 		}
+
+		name := strings.TrimSuffix(fi.Name(), ".gz")
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		if !filter.Match(pkg, dataStream, name) {
+			continue
+		}
+
 		files = append(files, fi.Name())
 	}
 	return files, nil
 }
 
-func (r *runner) loadTestCaseFile(testCaseFile string) (*testCase, error) {
-	testCasePath := filepath.Join(r.options.TestFolder.Path, testCaseFile)
-	testCaseData, err := ioutil.ReadFile(testCasePath)
+func (r *Runner) loadTestCaseFile(testFolderPath, testCaseFile string) (*testCase, error) {
+	testCasePath := filepath.Join(testFolderPath, testCaseFile)
+	testCaseData, err := r.FS.ReadFile(testCasePath)
 	if err != nil {
 		return nil, errors.Wrapf(err, "reading input file failed (testCasePath: %s)", testCasePath)
 	}
 
-	var tc *testCase
-	ext := filepath.Ext(testCaseFile)
-	switch ext {
-	case ".json":
-		tc, err = createTestCaseForEvents(testCaseFile, testCaseData)
-		if err != nil {
-			return nil, errors.Wrapf(err, "creating test case for events failed (testCasePath: %s)", testCasePath)
-		}
-	case ".log":
-		config, err := readConfigForTestCase(testCasePath)
-		if err != nil {
-			return nil, errors.Wrapf(err, "reading config for test case failed (testCasePath: %s)", testCasePath)
-		}
-		tc, err = createTestCaseForRawInput(testCaseFile, testCaseData, config)
-		if err != nil {
-			return nil, errors.Wrapf(err, "creating test case for events failed (testCasePath: %s)", testCasePath)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported extension for test case file (ext: %s)", ext)
+	tc, err := loadTestCaseFile(testCasePath, testCaseData)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating test case failed (testCasePath: %s)", testCasePath)
 	}
 	return tc, nil
 }
 
-func (r *runner) verifyResults(testCaseFile string, result *testResult) error {
-	testCasePath := filepath.Join(r.options.TestFolder.Path, testCaseFile)
-
-	if r.options.GenerateTestResult {
+func (r *Runner) verifyResults(testCasePath string, generateTestResult bool, result *testResult) error {
+	if generateTestResult {
 		err := writeTestResult(testCasePath, result)
 		if err != nil {
 			return errors.Wrap(err, "writing test result failed")
@@ -169,7 +248,3 @@ func (r *runner) verifyResults(testCaseFile string, result *testResult) error {
 	}
 	return nil
 }
-
-func init() {
-	testrunner.RegisterRunner(TestType, Run)
-}
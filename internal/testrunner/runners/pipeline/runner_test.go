@@ -0,0 +1,70 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+// TestRunConcurrentlyCancelledContextTrimsResults guards against a cancelled ctx producing
+// zero-value TestResults for items that were never scheduled, which reporters would render
+// as silently-passed, unnamed test cases.
+func TestRunConcurrentlyCancelledContextTrimsResults(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // cancelled before the loop even starts, so nothing should be scheduled
+
+	results, failed := runConcurrently(ctx, items, 1, func(i int, item string) (testrunner.TestResult, error) {
+		return testrunner.TestResult{Name: item}, nil
+	})
+
+	if failed {
+		t.Errorf("expected failed to be false, got true")
+	}
+	for _, r := range results {
+		if r.Name == "" {
+			t.Errorf("expected no zero-value results for unscheduled items, got: %+v", results)
+		}
+	}
+}
+
+func TestRunConcurrentlyRunsEveryItem(t *testing.T) {
+	items := []string{"a", "b", "c"}
+
+	results, failed := runConcurrently(context.Background(), items, 2, func(i int, item string) (testrunner.TestResult, error) {
+		return testrunner.TestResult{Name: item}, nil
+	})
+
+	if failed {
+		t.Errorf("expected failed to be false, got true")
+	}
+	if len(results) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(results))
+	}
+	for i, item := range items {
+		if results[i].Name != item {
+			t.Errorf("expected results[%d].Name to be %s, got %s", i, item, results[i].Name)
+		}
+	}
+}
+
+func TestRunConcurrentlyPropagatesFailure(t *testing.T) {
+	items := []string{"a", "b"}
+
+	_, failed := runConcurrently(context.Background(), items, 1, func(i int, item string) (testrunner.TestResult, error) {
+		if item == "b" {
+			return testrunner.TestResult{Name: item, FailureMsg: "boom"}, errTestCaseFailed
+		}
+		return testrunner.TestResult{Name: item}, nil
+	})
+
+	if !failed {
+		t.Errorf("expected failed to be true when one item fails")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package testrunner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+)
+
+// TestType represents the type of test that can be run (e.g. pipeline)
+type TestType string
+
+// TestFolder encapsulates the test folder path and names of the package and data stream.
+type TestFolder struct {
+	Path       string
+	Package    string
+	DataStream string
+}
+
+// TestOptions contains test runner options.
+type TestOptions struct {
+	TestFolder         TestFolder
+	PackageRootPath    string
+	GenerateTestResult bool
+	ESClient           *elasticsearch.Client
+
+	// NumIterations controls how many times a benchmark test case is run. Ignored by
+	// runners that don't repeat test cases (e.g. pipeline).
+	NumIterations int
+
+	// BaselinePath points at a JSON file of previously recorded metrics that a benchmark
+	// run is compared against. Ignored by runners that don't record metrics.
+	BaselinePath string
+
+	// RegressionThreshold is the fraction (e.g. 0.1 for 10%) a metric may regress against
+	// BaselinePath before the run is considered failed. Ignored if BaselinePath is empty.
+	RegressionThreshold float64
+
+	// PackageVersion is the version of the package under test, read from its manifest.
+	// Surfaced by reporters (e.g. as an xUnit <property>) alongside the package and data
+	// stream name.
+	PackageVersion string
+}
+
+// TestResult contains a single test's results
+type TestResult struct {
+	// TestType indicates the type of test (e.g. "pipeline")
+	TestType TestType
+
+	// Name indicates the name of the test
+	Name string
+
+	// Package indicates the package name
+	Package string
+
+	// DataStream indicates the data stream name
+	DataStream string
+
+	// Version indicates the version of the package under test, copied from
+	// TestOptions.PackageVersion.
+	Version string
+
+	// TimeElapsed indicates how much time the test took to run
+	TimeElapsed time.Duration
+
+	// Skipped indicates that the test has been skipped
+	Skipped bool
+
+	// SkipReason holds a human readable explanation of why the test was skipped
+	SkipReason string
+
+	// Stdout captures standard output produced while the test was running (e.g. an
+	// ingest pipeline simulation), surfaced by reporters as system-out.
+	Stdout string
+
+	// Stderr captures standard error produced while the test was running, surfaced
+	// by reporters as system-err.
+	Stderr string
+
+	// ErrorMsg contains the error description if the test failed because of an error
+	ErrorMsg string
+
+	// FailureMsg contains the failure description if the test failed because of a test failure
+	FailureMsg string
+
+	// FailureDetails contains Go specific details about the failure
+	FailureDetails string
+
+	// Iterations indicates how many times the test case was run to produce Metrics (e.g.
+	// for a benchmark test). Zero for test types that run a case once.
+	Iterations int
+
+	// Metrics holds named numeric measurements collected while running the test (e.g.
+	// throughput_eps, latency_p50_ms), surfaced by reporters as test properties.
+	Metrics map[string]float64
+}
+
+type runFunc func(ctx context.Context, options TestOptions, filter Filter) ([]TestResult, error)
+
+var runners = map[TestType]runFunc{}
+
+// RegisterRunner method registers the test runner for the given test type.
+func RegisterRunner(testType TestType, r runFunc) {
+	runners[testType] = r
+}
+
+// Run method delegates execution to the test runner registered for the given test type.
+// The filter restricts the run to matching test cases; ctx cancellation (e.g. on SIGINT)
+// stops in-flight test cases and still allows runners to clean up after themselves.
+func Run(ctx context.Context, testType TestType, options TestOptions, filter Filter) ([]TestResult, error) {
+	runner, defined := runners[testType]
+	if !defined {
+		return nil, fmt.Errorf("unregistered runner test: %s", testType)
+	}
+	return runner(ctx, options, filter)
+}